@@ -0,0 +1,139 @@
+package sauce
+
+import "fmt"
+
+// TInfoDecoder formats a record's TInfo/TFlags fields as a human-readable
+// string for Dump, e.g. "80 x 25 characters" for ANSi. It returns "" if it
+// has nothing to say about r.
+type TInfoDecoder func(r *SAUCE) string
+
+// Registry holds DataType/FileType name tables and the TInfoDecoders used
+// to render them, so callers can add new (DataType, FileType) pairs, such
+// as a modern tracker format or a vendor-specific ANSI dialect, without
+// racing on shared package-level maps.
+type Registry struct {
+	dataTypes map[uint8]string
+	fileTypes map[uint8]map[uint8]string
+	tinfo     map[[2]uint8]TInfoDecoder
+	dtTinfo   map[uint8]TInfoDecoder
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		dataTypes: make(map[uint8]string),
+		fileTypes: make(map[uint8]map[uint8]string),
+		tinfo:     make(map[[2]uint8]TInfoDecoder),
+		dtTinfo:   make(map[uint8]TInfoDecoder),
+	}
+}
+
+// RegisterDataType names a DataType value.
+func (reg *Registry) RegisterDataType(id uint8, name string) {
+	reg.dataTypes[id] = name
+}
+
+// RegisterFileType names the (dt, ft) FileType pair and, if decode is
+// non-nil, registers how to render its TInfo/TFlags fields for Dump. A
+// blank name leaves any name already registered for (dt, ft) untouched,
+// which lets callers attach a decoder to a pair without renaming it.
+func (reg *Registry) RegisterFileType(dt, ft uint8, name string, decode TInfoDecoder) {
+	if reg.fileTypes[dt] == nil {
+		reg.fileTypes[dt] = make(map[uint8]string)
+	}
+	if name != "" {
+		reg.fileTypes[dt][ft] = name
+	}
+	if decode != nil {
+		reg.tinfo[[2]uint8{dt, ft}] = decode
+	}
+}
+
+// RegisterDataTypeDecoder registers a TInfoDecoder for every FileType of dt.
+// Use this instead of RegisterFileType for DataTypes whose FileType byte
+// isn't a name enum (BinaryText's is the record's width/2, not a type id),
+// so it can't be matched by the (DataType, FileType) pair alone.
+func (reg *Registry) RegisterDataTypeDecoder(dt uint8, decode TInfoDecoder) {
+	reg.dtTinfo[dt] = decode
+}
+
+// DataTypeString returns the name registered for id, or "" if none was.
+func (reg *Registry) DataTypeString(id uint8) string {
+	return reg.dataTypes[id]
+}
+
+// FileTypeString returns the name registered for (dt, ft), or "" if none
+// was.
+func (reg *Registry) FileTypeString(dt, ft uint8) string {
+	return reg.fileTypes[dt][ft]
+}
+
+// TInfoString renders r's TInfo/TFlags fields using the TInfoDecoder
+// registered for (r.DataType, r.FileType), falling back to one registered
+// for r.DataType as a whole via RegisterDataTypeDecoder. It returns "" if
+// neither was registered.
+func (reg *Registry) TInfoString(r *SAUCE) string {
+	if decode, ok := reg.tinfo[[2]uint8{r.DataType, r.FileType}]; ok {
+		return decode(r)
+	}
+	if decode, ok := reg.dtTinfo[r.DataType]; ok {
+		return decode(r)
+	}
+	return ""
+}
+
+// DefaultRegistry is the Registry consulted by (*SAUCE).DataTypeString,
+// (*SAUCE).FileTypeString and Dump. It starts out seeded from SAUCEDataType
+// and SAUCEFileType; register additional types on it directly, or build an
+// isolated Registry for use cases that shouldn't see each other's types.
+var DefaultRegistry = NewRegistry()
+
+func init() {
+	for id, name := range SAUCEDataType {
+		DefaultRegistry.RegisterDataType(id, name)
+	}
+	for dt, files := range SAUCEFileType {
+		for ft, name := range files {
+			DefaultRegistry.RegisterFileType(dt, ft, name, tinfoDecoderFor(dt, ft))
+		}
+	}
+	// BinaryText has no FileType name table -- its FileType byte is the
+	// record's width/2, not a type id -- so it can't piggyback on the
+	// (DataType, FileType) loop above.
+	DefaultRegistry.RegisterDataTypeDecoder(DATA_TYPE_BINARYTEXT, binaryTextTInfo)
+}
+
+// tinfoDecoderFor returns the built-in TInfoDecoder for a known (dt, ft)
+// pair, mirroring the size line Dump printed before the Registry existed.
+func tinfoDecoderFor(dt, ft uint8) TInfoDecoder {
+	switch dt {
+	case DATA_TYPE_CHARACTER:
+		switch ft {
+		case 0, 1, 2, 4, 5, 8:
+			return characterTInfo
+		case 3:
+			return pixelTInfo
+		}
+	case DATA_TYPE_BITMAP:
+		return pixelTInfo
+	case DATA_TYPE_XBIN:
+		return characterTInfo
+	}
+	return nil
+}
+
+func characterTInfo(r *SAUCE) string {
+	w := r.TInfo[0]
+	if w == 0 {
+		w = 80
+	}
+	return fmt.Sprintf("%d x %d characters", w, r.TInfo[1])
+}
+
+func pixelTInfo(r *SAUCE) string {
+	return fmt.Sprintf("%d x %d pixels", r.TInfo[0], r.TInfo[1])
+}
+
+func binaryTextTInfo(r *SAUCE) string {
+	return fmt.Sprintf("%d x %d characters", int(r.FileType)*2, r.TInfo[1])
+}
@@ -0,0 +1,26 @@
+package sauce
+
+import "testing"
+
+func TestDefaultRegistryBinaryTextTInfoString(t *testing.T) {
+	r := New()
+	r.DataType = DATA_TYPE_BINARYTEXT
+	r.FileType = 80 // width/2 convention: 160 columns
+	r.TInfo[1] = 25
+
+	got := DefaultRegistry.TInfoString(r)
+	want := "160 x 25 characters"
+	if got != want {
+		t.Errorf("TInfoString = %q, want %q", got, want)
+	}
+}
+
+func TestRegistryFileTypeNameSurvivesDecoderRegistration(t *testing.T) {
+	reg := NewRegistry()
+	reg.RegisterFileType(DATA_TYPE_CHARACTER, 1, "ANSi", nil)
+	reg.RegisterFileType(DATA_TYPE_CHARACTER, 1, "", characterTInfo)
+
+	if got := reg.FileTypeString(DATA_TYPE_CHARACTER, 1); got != "ANSi" {
+		t.Errorf("FileTypeString = %q, want %q", got, "ANSi")
+	}
+}
@@ -0,0 +1,135 @@
+package render
+
+import sauce "github.com/tehmaze-labs/go-sauce"
+
+// renderANSI lays out Character/ANSi payload bytes (CSI SGR and cursor
+// movement, CR/LF) onto a grid s.TInfo[0] columns wide, defaulting to 80
+// per the SAUCE spec.
+func renderANSI(payload []byte, s *sauce.SAUCE) *grid {
+	width := int(s.TInfo[0])
+	if width == 0 {
+		width = 80
+	}
+	g := newGrid(width)
+
+	x, y := 0, 0
+	fg, bg := uint8(7), uint8(0)
+	ice := s.IceColors()
+
+	for i := 0; i < len(payload); {
+		b := payload[i]
+		switch {
+		case b == 0x1b && i+1 < len(payload) && payload[i+1] == '[':
+			end, params, final := parseCSI(payload, i+2)
+			i = end
+			switch final {
+			case 'm':
+				fg, bg = applySGR(params, fg, bg, ice)
+			case 'A':
+				y -= csiArg(params, 0, 1)
+			case 'B':
+				y += csiArg(params, 0, 1)
+			case 'C':
+				x += csiArg(params, 0, 1)
+			case 'D':
+				x -= csiArg(params, 0, 1)
+			case 'H', 'f':
+				y = csiArg(params, 0, 1) - 1
+				x = csiArg(params, 1, 1) - 1
+			}
+			if x < 0 {
+				x = 0
+			}
+			if y < 0 {
+				y = 0
+			}
+		case b == '\r':
+			x = 0
+			i++
+		case b == '\n':
+			x = 0
+			y++
+			i++
+		default:
+			g.set(x, y, cell{ch: b, fg: fg, bg: bg})
+			x++
+			if x >= width {
+				x = 0
+				y++
+			}
+			i++
+		}
+	}
+	return g
+}
+
+// parseCSI scans a CSI sequence starting at i (just past "ESC ["), returning
+// the index past its final byte, the parsed numeric parameters and the
+// final byte itself.
+func parseCSI(b []byte, i int) (end int, params []int, final byte) {
+	start := i
+	for i < len(b) && ((b[i] >= '0' && b[i] <= '9') || b[i] == ';') {
+		i++
+	}
+	params = parseParams(b[start:i])
+	if i < len(b) {
+		final = b[i]
+		i++
+	}
+	return i, params, final
+}
+
+func parseParams(b []byte) []int {
+	var params []int
+	n, has := 0, false
+	for _, c := range b {
+		if c == ';' {
+			params = append(params, n)
+			n, has = 0, false
+			continue
+		}
+		n = n*10 + int(c-'0')
+		has = true
+	}
+	if has || len(params) == 0 {
+		params = append(params, n)
+	}
+	return params
+}
+
+func csiArg(params []int, idx, def int) int {
+	if idx < len(params) && params[idx] != 0 {
+		return params[idx]
+	}
+	return def
+}
+
+// applySGR updates fg/bg (4-bit palette index plus intensity bit) per a CSI
+// "m" sequence's parameters. With iCE colors, SGR 5 (blink) selects a bright
+// background like bold selects a bright foreground; without it, SGR 5 would
+// blink rather than change color, so it's left alone for a static render.
+func applySGR(params []int, fg, bg uint8, ice bool) (uint8, uint8) {
+	for _, p := range params {
+		switch {
+		case p == 0:
+			fg, bg = 7, 0
+		case p == 1:
+			fg |= 0x08
+		case p == 5 && ice:
+			bg |= 0x08
+		case p >= 30 && p <= 37:
+			fg = fg&0x08 | uint8(p-30)
+		case p == 39:
+			fg = fg&0x08 | 7
+		case p >= 40 && p <= 47:
+			bg = bg&0x08 | uint8(p-40)
+		case p == 49:
+			bg = bg & 0x08
+		case p >= 90 && p <= 97:
+			fg = 0x08 | uint8(p-90)
+		case p >= 100 && p <= 107:
+			bg = 0x08 | uint8(p-100)
+		}
+	}
+	return fg, bg
+}
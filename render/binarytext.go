@@ -0,0 +1,25 @@
+package render
+
+import sauce "github.com/tehmaze-labs/go-sauce"
+
+// renderBinaryText lays out paired character/attribute bytes onto a grid
+// FileType*2 characters wide, per the SAUCE BinaryText convention.
+func renderBinaryText(payload []byte, s *sauce.SAUCE) *grid {
+	width := int(s.FileType) * 2
+	if width == 0 {
+		width = 160
+	}
+	g := newGrid(width)
+
+	x, y := 0, 0
+	for i := 0; i+1 < len(payload); i += 2 {
+		attr := payload[i+1]
+		g.set(x, y, cell{ch: payload[i], fg: attr & 0x0f, bg: (attr >> 4) & 0x0f})
+		x++
+		if x >= width {
+			x = 0
+			y++
+		}
+	}
+	return g
+}
@@ -0,0 +1,54 @@
+package render
+
+import _ "embed"
+
+//go:embed fonts/cp437-8x16.f16
+var defaultFontData []byte
+
+// Font is a fixed-width bitmap font: up to 256 glyphs, each Height rows of
+// Width bits packed MSB-first into one byte per row (fonts wider than 8
+// pixels are not supported; SAUCE fonts never are).
+type Font struct {
+	Name   string
+	Width  int
+	Height int
+	Glyphs [256][]byte
+}
+
+// DefaultFont is the built-in font used when a SAUCE record names no font,
+// or one render.Fonts doesn't know about. See the package doc for the scope
+// of what it actually renders correctly (box-drawing/shade glyphs and
+// printable ASCII; the rest of the CP437 high range falls back to a
+// placeholder outline).
+var DefaultFont = loadFont("IBM VGA", 8, 16, defaultFontData)
+
+// Fonts maps SAUCE font names (as read from TInfoS) to a Font used to
+// render Character/BinaryText/XBin art. Only the built-in "IBM VGA" is
+// registered by default; add others with RegisterFont.
+var Fonts = map[string]*Font{
+	"IBM VGA": DefaultFont,
+}
+
+// RegisterFont makes f available under name for future Render calls.
+func RegisterFont(name string, f *Font) {
+	Fonts[name] = f
+}
+
+// loadFont slices data into height-row, 1-byte-per-row glyphs.
+func loadFont(name string, width, height int, data []byte) *Font {
+	f := &Font{Name: name, Width: width, Height: height}
+	glyphBytes := height // 1 byte per row for width <= 8
+	for i := 0; i < 256 && (i+1)*glyphBytes <= len(data); i++ {
+		f.Glyphs[i] = data[i*glyphBytes : (i+1)*glyphBytes]
+	}
+	return f
+}
+
+// glyph returns the bitmap rows for ch, falling back to glyph 0 (blank in
+// the built-in font) if ch has none.
+func (f *Font) glyph(ch byte) []byte {
+	if g := f.Glyphs[ch]; g != nil {
+		return g
+	}
+	return f.Glyphs[0]
+}
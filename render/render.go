@@ -0,0 +1,173 @@
+// Package render draws ANSI, BinaryText and XBin artwork to an image,
+// using the width, font, letter-spacing and iCE colors flag recorded in its
+// SAUCE record to pick the right layout.
+//
+// The built-in DefaultFont has real letterforms for the CP437 box-drawing
+// and shade characters (176-223, what most ANSI/BIN block art is drawn
+// from) and for printable ASCII (33-126, covering plain text like titles,
+// credits and menu labels). Codepoints outside those two ranges - the rest
+// of the CP437 high range (127-175, 224-255): accented letters, Greek,
+// currency and line-drawing corner variants - still fall back to a
+// placeholder outline. Artwork that leans on those needs a real font
+// registered with RegisterFont before rendering.
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	sauce "github.com/tehmaze-labs/go-sauce"
+)
+
+// Palette is the 16-color CGA/VGA palette ANSI and BIN art is drawn with.
+var Palette = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff},
+	{0xAA, 0x00, 0x00, 0xff},
+	{0x00, 0xAA, 0x00, 0xff},
+	{0xAA, 0x55, 0x00, 0xff},
+	{0x00, 0x00, 0xAA, 0xff},
+	{0xAA, 0x00, 0xAA, 0xff},
+	{0x00, 0xAA, 0xAA, 0xff},
+	{0xAA, 0xAA, 0xAA, 0xff},
+	{0x55, 0x55, 0x55, 0xff},
+	{0xFF, 0x55, 0x55, 0xff},
+	{0x55, 0xFF, 0x55, 0xff},
+	{0xFF, 0xFF, 0x55, 0xff},
+	{0x55, 0x55, 0xFF, 0xff},
+	{0xFF, 0x55, 0xFF, 0xff},
+	{0x55, 0xFF, 0xFF, 0xff},
+	{0xFF, 0xFF, 0xFF, 0xff},
+}
+
+// cell is one character position on the canvas.
+type cell struct {
+	ch     byte
+	fg, bg uint8
+}
+
+// grid is a rectangular buffer of cells, width columns wide and as many
+// rows as were touched while drawing.
+type grid struct {
+	width int
+	rows  [][]cell
+}
+
+func newGrid(width int) *grid {
+	if width <= 0 {
+		width = 80
+	}
+	return &grid{width: width}
+}
+
+func (g *grid) row(y int) []cell {
+	for len(g.rows) <= y {
+		r := make([]cell, g.width)
+		for i := range r {
+			r[i] = cell{ch: ' '}
+		}
+		g.rows = append(g.rows, r)
+	}
+	return g.rows[y]
+}
+
+func (g *grid) set(x, y int, c cell) {
+	if x < 0 || x >= g.width || y < 0 {
+		return
+	}
+	g.row(y)[x] = c
+}
+
+// Render draws payload (the artwork bytes preceding the ASCIISub+SAUCE
+// trailer) to an RGBA image, dispatching on s.DataType/s.FileType. XBin
+// files carrying a custom palette in their header render with it in place
+// of the default Palette.
+func Render(payload []byte, s *sauce.SAUCE) (*image.RGBA, error) {
+	font := fontFor(s)
+	cellWidth := font.Width
+	pal := Palette
+
+	var g *grid
+	switch s.DataType {
+	case sauce.DATA_TYPE_XBIN:
+		var err error
+		var xbinPal *[16]color.RGBA
+		g, font, xbinPal, err = renderXBin(payload, s, font)
+		if err != nil {
+			return nil, err
+		}
+		if xbinPal != nil {
+			pal = *xbinPal
+		}
+	case sauce.DATA_TYPE_BINARYTEXT:
+		g = renderBinaryText(payload, s)
+	default:
+		g = renderANSI(payload, s)
+		if s.LetterSpacing() == sauce.LetterSpacing9Pixel {
+			cellWidth = 9
+		}
+	}
+
+	return paint(g, font, cellWidth, &pal), nil
+}
+
+// RenderPNG renders payload as Render does and writes it to w as a PNG.
+func RenderPNG(w io.Writer, payload []byte, s *sauce.SAUCE) error {
+	img, err := Render(payload, s)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// fontFor picks the font s declares via TInfoS, falling back to DefaultFont
+// if it names none render.Fonts knows about.
+func fontFor(s *sauce.SAUCE) *Font {
+	if name := s.FontName(); name != "" {
+		if f, ok := Fonts[name]; ok {
+			return f
+		}
+	}
+	return DefaultFont
+}
+
+func paint(g *grid, font *Font, cellWidth int, pal *[16]color.RGBA) *image.RGBA {
+	width := g.width * cellWidth
+	height := len(g.rows) * font.Height
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y, row := range g.rows {
+		for x, c := range row {
+			drawGlyph(img, font, c, x*cellWidth, y*font.Height, cellWidth, pal)
+		}
+	}
+	return img
+}
+
+// drawGlyph paints one cell's glyph bitmap into img at (ox, oy). Fonts are
+// rendered at a fixed 8 bits per row; a 9-pixel cell repeats the rightmost
+// column, matching how VGA text modes stretch CP437 box-drawing glyphs.
+func drawGlyph(img *image.RGBA, font *Font, c cell, ox, oy, cellWidth int, pal *[16]color.RGBA) {
+	fg := pal[c.fg&0x0f]
+	bg := pal[c.bg&0x0f]
+	bits := font.glyph(c.ch)
+
+	for row := 0; row < font.Height; row++ {
+		var b byte
+		if row < len(bits) {
+			b = bits[row]
+		}
+		for col := 0; col < cellWidth; col++ {
+			bit := col
+			if bit >= 8 {
+				bit = 7
+			}
+			px := bg
+			if b&(0x80>>uint(bit)) != 0 {
+				px = fg
+			}
+			img.Set(ox+col, oy+row, px)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package render_test
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	sauce "github.com/tehmaze-labs/go-sauce"
+	"github.com/tehmaze-labs/go-sauce/render"
+)
+
+// TestRenderXBinFontOffset is a golden-byte regression test for the XBin
+// header parsing: FontSize lives at a fixed header offset (9), not as the
+// first byte of the embedded font blob. An all-zero font blob makes every
+// glyph render as flat background, so the two decoded cells' colors are
+// purely a function of the header (and thus the font-blob skip) being
+// parsed correctly.
+func TestRenderXBinFontOffset(t *testing.T) {
+	header := []byte{'X', 'B', 'I', 'N', 0x1a, 2, 0, 1, 0, 16, 0x02} // width=2, height=1, fontHeight=16, flags=font-only
+	fontBlob := make([]byte, 256*16)
+	charData := []byte{'A', 0x1f, 'B', 0x2e}
+	payload := append(append(append([]byte{}, header...), fontBlob...), charData...)
+
+	s := sauce.New()
+	s.DataType = sauce.DATA_TYPE_XBIN
+
+	img, err := render.Render(payload, s)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	b := img.Bounds()
+	if b.Dx() != 16 || b.Dy() != 16 {
+		t.Fatalf("bounds = %dx%d, want 16x16", b.Dx(), b.Dy())
+	}
+
+	if got, want := img.At(0, 0), render.Palette[1]; got != want {
+		t.Errorf("cell 0 background = %v, want %v (attr 0x1f -> bg=1)", got, want)
+	}
+	if got, want := img.At(8, 0), render.Palette[2]; got != want {
+		t.Errorf("cell 1 background = %v, want %v (attr 0x2e -> bg=2)", got, want)
+	}
+}
+
+// TestRenderBinaryText checks that BinaryText's paired char/attribute bytes
+// decode into the right background colors (FileType*2 columns wide).
+func TestRenderBinaryText(t *testing.T) {
+	s := sauce.New()
+	s.DataType = sauce.DATA_TYPE_BINARYTEXT
+	s.FileType = 1 // width = 2 characters
+
+	payload := []byte{'A', 0x1f, 'B', 0x2e}
+	img, err := render.Render(payload, s)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	b := img.Bounds()
+	if b.Dx() != 16 || b.Dy() != 16 {
+		t.Fatalf("bounds = %dx%d, want 16x16", b.Dx(), b.Dy())
+	}
+
+	// The default font's placeholder glyph has a blank top row, so (0,0)
+	// and (8,0) are always background, regardless of which glyph is drawn.
+	if got, want := img.At(0, 0), render.Palette[1]; got != want {
+		t.Errorf("cell 0 background = %v, want %v (attr 0x1f -> bg=1)", got, want)
+	}
+	if got, want := img.At(8, 0), render.Palette[2]; got != want {
+		t.Errorf("cell 1 background = %v, want %v (attr 0x2e -> bg=2)", got, want)
+	}
+}
+
+// TestDefaultFontHasRealLetterforms guards against DefaultFont's printable
+// ASCII glyphs regressing back into the identical placeholder outline they
+// used to render as.
+func TestDefaultFontHasRealLetterforms(t *testing.T) {
+	a := render.DefaultFont.Glyphs['A']
+	lower := render.DefaultFont.Glyphs['a']
+	digit := render.DefaultFont.Glyphs['0']
+
+	if bytes.Equal(a, lower) {
+		t.Error("'A' and 'a' render as the same glyph, want distinct letterforms")
+	}
+	if bytes.Equal(a, digit) {
+		t.Error("'A' and '0' render as the same glyph, want distinct letterforms")
+	}
+}
+
+// TestRenderXBinCustomPalette is a golden-byte regression test for XBin's
+// custom-palette header flag: the 16 RGB triples preceding the character
+// data must replace render.Palette, not merely be skipped over.
+func TestRenderXBinCustomPalette(t *testing.T) {
+	header := []byte{'X', 'B', 'I', 'N', 0x1a, 2, 0, 1, 0, 16, 0x01} // width=2, height=1, flags=palette-only
+	palette := make([]byte, 16*3)
+	palette[1*3+0], palette[1*3+1], palette[1*3+2] = 0x3f, 0x00, 0x00 // color 1 -> pure red
+	palette[2*3+0], palette[2*3+1], palette[2*3+2] = 0x00, 0x3f, 0x00 // color 2 -> pure green
+	charData := []byte{'A', 0x1f, 'B', 0x2e}
+	payload := append(append(append([]byte{}, header...), palette...), charData...)
+
+	s := sauce.New()
+	s.DataType = sauce.DATA_TYPE_XBIN
+
+	img, err := render.Render(payload, s)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	red := color.RGBA{R: 0xff, G: 0x00, B: 0x00, A: 0xff}
+	green := color.RGBA{R: 0x00, G: 0xff, B: 0x00, A: 0xff}
+	if got := img.At(0, 0); got != red {
+		t.Errorf("cell 0 background = %v, want %v (custom palette color 1 -> red)", got, red)
+	}
+	if got := img.At(8, 0); got != green {
+		t.Errorf("cell 1 background = %v, want %v (custom palette color 2 -> green)", got, green)
+	}
+}
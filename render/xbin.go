@@ -0,0 +1,143 @@
+package render
+
+import (
+	"encoding/binary"
+	"errors"
+	"image/color"
+
+	sauce "github.com/tehmaze-labs/go-sauce"
+)
+
+var errNotXBin = errors.New("render: not an XBin file")
+
+// XBin header flag bits (offset 10 of the header).
+const (
+	xbinFlagPalette  = 1 << 0
+	xbinFlagFont     = 1 << 1
+	xbinFlagCompress = 1 << 2
+	xbinFlagNonBlink = 1 << 3
+	xbinFlag512Chars = 1 << 4
+)
+
+// renderXBin parses an XBin header (dimensions and an optional custom
+// palette/font) and lays its RLE-compressed or raw character data onto a
+// grid. It returns the font the header declares, which replaces font per
+// the XBin spec when the header carries one, and the palette the header
+// declares (nil if it carries none, leaving the caller's default palette).
+func renderXBin(payload []byte, s *sauce.SAUCE, font *Font) (*grid, *Font, *[16]color.RGBA, error) {
+	if len(payload) < 11 || string(payload[0:4]) != "XBIN" {
+		return nil, nil, nil, errNotXBin
+	}
+
+	width := int(binary.LittleEndian.Uint16(payload[5:7]))
+	height := int(binary.LittleEndian.Uint16(payload[7:9]))
+	fontHeight := int(payload[9])
+	flags := payload[10]
+	i := 11
+
+	if fontHeight == 0 {
+		fontHeight = 16
+	}
+	glyphCount := 256
+	if flags&xbinFlag512Chars != 0 {
+		glyphCount = 512
+	}
+	if flags&xbinFlagFont != 0 {
+		n := glyphCount * fontHeight
+		if i+n > len(payload) {
+			return nil, nil, nil, errNotXBin
+		}
+		font = loadFont(s.FontName(), 8, fontHeight, payload[i:i+n])
+		i += n
+	}
+	var palette *[16]color.RGBA
+	if flags&xbinFlagPalette != 0 {
+		// 16 colors * 3 bytes of 6-bit VGA RGB precede the character data.
+		n := 16 * 3
+		if i+n > len(payload) {
+			return nil, nil, nil, errNotXBin
+		}
+		palette = parsePalette(payload[i : i+n])
+		i += n
+	}
+
+	g := newGrid(width)
+	x, y := 0, 0
+	put := func(ch, attr byte) {
+		g.set(x, y, cell{ch: ch, fg: attr & 0x0f, bg: (attr >> 4) & 0x0f})
+		x++
+		if x >= width {
+			x = 0
+			y++
+		}
+	}
+
+	data := payload[i:]
+	if flags&xbinFlagCompress == 0 {
+		for j := 0; j+1 < len(data) && y < height; j += 2 {
+			put(data[j], data[j+1])
+		}
+		return g, font, palette, nil
+	}
+
+	for j := 0; j < len(data) && y < height; {
+		ctrl := data[j]
+		j++
+		count := int(ctrl&0x3f) + 1
+		switch ctrl >> 6 {
+		case 0: // uncompressed run
+			for k := 0; k < count && j+1 < len(data); k++ {
+				put(data[j], data[j+1])
+				j += 2
+			}
+		case 1: // same character, varying attribute
+			if j >= len(data) {
+				return g, font, palette, nil
+			}
+			ch := data[j]
+			j++
+			for k := 0; k < count && j < len(data); k++ {
+				put(ch, data[j])
+				j++
+			}
+		case 2: // same attribute, varying character
+			if j >= len(data) {
+				return g, font, palette, nil
+			}
+			attr := data[j]
+			j++
+			for k := 0; k < count && j < len(data); k++ {
+				put(data[j], attr)
+				j++
+			}
+		case 3: // same character and attribute
+			if j+1 >= len(data) {
+				return g, font, palette, nil
+			}
+			ch, attr := data[j], data[j+1]
+			j += 2
+			for k := 0; k < count; k++ {
+				put(ch, attr)
+			}
+		}
+	}
+	return g, font, palette, nil
+}
+
+// parsePalette decodes 16 entries of 6-bit-per-channel VGA RGB (3 bytes
+// each, 0-63 range) into the 8-bit-per-channel palette drawGlyph expects.
+func parsePalette(data []byte) *[16]color.RGBA {
+	var pal [16]color.RGBA
+	for i := range pal {
+		r, g, b := data[i*3], data[i*3+1], data[i*3+2]
+		pal[i] = color.RGBA{R: scale6to8(r), G: scale6to8(g), B: scale6to8(b), A: 0xff}
+	}
+	return &pal
+}
+
+// scale6to8 expands a 6-bit VGA color channel (0-63) to 8 bits (0-255) by
+// replicating its top 2 bits into the low bits, so 0 maps to 0 and 63 maps
+// to 255 instead of leaving the low end of the 8-bit range unreachable.
+func scale6to8(v byte) uint8 {
+	return uint8(v<<2 | v>>4)
+}
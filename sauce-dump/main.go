@@ -3,13 +3,15 @@ package main
 import (
 	"flag"
 	"fmt"
+
+	sauce "github.com/tehmaze-labs/go-sauce"
 )
 
 func main() {
 	flag.Parse()
 
 	for _, filename := range flag.Args() {
-		r, err := suace.Parse(filename)
+		r, err := sauce.Parse(filename)
 		if err != nil {
 			fmt.Printf("%s: error %v\n", filename, err)
 			continue
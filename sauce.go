@@ -6,8 +6,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -117,24 +119,32 @@ var (
 			8: "PAK",
 			9: "SQZ",
 		},
+		DATA_TYPE_XBIN: map[uint8]string{
+			0: "XBin",
+		},
 	}
 )
 
 // SAUCE (Standard Architecture for Universal Comment Extensions) record.
 type SAUCE struct {
-	ID       [5]byte
-	Version  [2]byte
-	Title    string
-	Author   string
-	Group    string
-	Date     time.Time
-	FileSize uint32
-	DataType uint8
-	FileType uint8
-	TInfo    [4]uint16
-	Comments uint8
-	TFlags   uint8
-	TInfos   [22]byte
+	ID          [5]byte
+	Version     [2]byte
+	Title       string
+	Author      string
+	Group       string
+	Date        time.Time
+	FileSize    uint32
+	DataType    uint8
+	FileType    uint8
+	TInfo       [4]uint16
+	NumComments uint8
+	TFlags      uint8
+	TInfoS      [22]byte
+
+	// CommentLines holds the lines of the CommentBlock (COMNT subrecord)
+	// that precedes the SAUCE record, if any. Its length is written as
+	// the record's NumComments count byte.
+	CommentLines []string
 }
 
 // New creates an empty SAUCE record.
@@ -145,66 +155,313 @@ func New() *SAUCE {
 	}
 }
 
-// Parse SAUCE record from a file.
-func Parse(filename string) (r *SAUCE, err error) {
-	var f *os.File
-	var i os.FileInfo
+// Sentinel errors returned by Parse, ParseReader and ParseBytes.
+var (
+	ErrTooShort  = errors.New("sauce: file too short for a SAUCE record")
+	ErrShortRead = errors.New("sauce: short read")
+	ErrNoRecord  = errors.New("sauce: no SAUCE record")
+	ErrBadDate   = errors.New("sauce: invalid date")
+)
+
+// Logger receives diagnostic output from Parse/ParseReader/ParseBytes when
+// set. It is nil (disabled) by default.
+var Logger *log.Logger
+
+func logf(format string, args ...interface{}) {
+	if Logger != nil {
+		Logger.Printf(format, args...)
+	}
+}
 
-	f, err = os.Open(filename)
+// Parse reads a SAUCE record from the end of filename.
+func Parse(filename string) (*SAUCE, error) {
+	f, err := os.Open(filename)
 	if err != nil {
-		return
+		return nil, err
 	}
 	defer f.Close()
 
-	i, err = f.Stat()
+	i, err := f.Stat()
 	if err != nil {
-		return
+		return nil, err
 	}
-	if i.Size() < 129 {
-		return nil, errors.New("File too short")
+	return ParseReader(f, i.Size())
+}
+
+// ParseBytes reads a SAUCE record from the end of b.
+func ParseBytes(b []byte) (*SAUCE, error) {
+	return ParseReader(bytes.NewReader(b), int64(len(b)))
+}
+
+// ParseReader reads a SAUCE record from the last 128 bytes of r, which is
+// assumed to hold size bytes in total. This allows parsing from anything
+// that supports random access, such as an open file, an archive entry or an
+// in-memory buffer, not just a path on disk.
+func ParseReader(r io.ReaderAt, size int64) (*SAUCE, error) {
+	if size < 129 {
+		return nil, ErrTooShort
 	}
 
-	var n int
-	_, err = f.Seek(-128, 2)
+	b := make([]byte, 128)
+	n, err := r.ReadAt(b, size-128)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n != 128 {
+		return nil, ErrShortRead
+	}
+	if !bytes.Equal(b[0:5], SAUCEID[:]) {
+		return nil, ErrNoRecord
+	}
+
+	rec := New()
+	rec.Title = strings.TrimSpace(string(b[7:42]))
+	rec.Author = strings.TrimSpace(string(b[42:62]))
+	rec.Group = strings.TrimSpace(string(b[62:82]))
+	logf("sauce: date %q", string(b[82:90]))
+	date, err := parseDate(string(b[82:90]))
 	if err != nil {
-		return
+		return nil, err
+	}
+	rec.Date = date
+	rec.FileSize = binary.LittleEndian.Uint32(b[90:94])
+	rec.DataType = b[94]
+	rec.FileType = b[95]
+	rec.TInfo[0] = binary.LittleEndian.Uint16(b[96:98])
+	rec.TInfo[1] = binary.LittleEndian.Uint16(b[98:100])
+	rec.TInfo[2] = binary.LittleEndian.Uint16(b[100:102])
+	rec.TInfo[3] = binary.LittleEndian.Uint16(b[102:104])
+	rec.NumComments = b[104]
+	rec.TFlags = b[105]
+	copy(rec.TInfoS[:], b[106:128])
+
+	if rec.NumComments > 0 {
+		cbLen := 5 + 64*int(rec.NumComments)
+		if start := size - 128 - int64(cbLen); start >= 0 {
+			cb := make([]byte, cbLen)
+			if cn, cerr := r.ReadAt(cb, start); cerr == nil && cn == cbLen && bytes.Equal(cb[0:5], []byte("COMNT")) {
+				lines := make([]string, rec.NumComments)
+				for i := range lines {
+					lines[i] = strings.TrimSpace(string(cb[5+64*i : 5+64*(i+1)]))
+				}
+				rec.CommentLines = lines
+			}
+		}
 	}
+
+	return rec, nil
+}
+
+// maxComments is the largest comment line count the Comments byte can hold.
+const maxComments = 255
+
+// AddComment appends a comment line to r, keeping NumComments in sync. It
+// returns an error if r already carries the maximum of 255 lines.
+func (r *SAUCE) AddComment(line string) error {
+	if len(r.CommentLines) >= maxComments {
+		return errors.New("sauce: too many comment lines")
+	}
+	r.CommentLines = append(r.CommentLines, line)
+	r.NumComments = uint8(len(r.CommentLines))
+	return nil
+}
+
+// SetComments replaces r's comment lines wholesale, keeping NumComments in
+// sync. It returns an error if lines exceeds the maximum of 255 lines.
+func (r *SAUCE) SetComments(lines []string) error {
+	if len(lines) > maxComments {
+		return errors.New("sauce: too many comment lines")
+	}
+	r.CommentLines = lines
+	r.NumComments = uint8(len(lines))
+	return nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	if len(s) != 8 {
+		return time.Time{}, ErrBadDate
+	}
+	y, errY := strconv.Atoi(s[:4])
+	m, errM := strconv.Atoi(s[4:6])
+	d, errD := strconv.Atoi(s[6:8])
+	if errY != nil || errM != nil || errD != nil {
+		return time.Time{}, ErrBadDate
+	}
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC), nil
+}
+
+// Bytes encodes r as a spec-conformant 128-byte SAUCE record: fixed-width
+// fields are space-padded or truncated to fit, FileSize/TInfo are written
+// little-endian, and the Comments count byte reflects len(r.CommentLines).
+func (r *SAUCE) Bytes() ([]byte, error) {
 	b := make([]byte, 128)
-	n, err = f.Read(b)
+	copy(b[0:5], r.ID[:])
+	copy(b[5:7], r.Version[:])
+	putString(b[7:42], r.Title)
+	putString(b[42:62], r.Author)
+	putString(b[62:82], r.Group)
+
+	if r.Date.IsZero() {
+		copy(b[82:90], []byte(SAUCEDate))
+	} else {
+		copy(b[82:90], []byte(r.Date.Format("20060102")))
+	}
+
+	binary.LittleEndian.PutUint32(b[90:94], r.FileSize)
+	b[94] = r.DataType
+	b[95] = r.FileType
+	binary.LittleEndian.PutUint16(b[96:98], r.TInfo[0])
+	binary.LittleEndian.PutUint16(b[98:100], r.TInfo[1])
+	binary.LittleEndian.PutUint16(b[100:102], r.TInfo[2])
+	binary.LittleEndian.PutUint16(b[102:104], r.TInfo[3])
+	b[104] = uint8(len(r.CommentLines))
+	b[105] = r.TFlags
+	copy(b[106:128], r.TInfoS[:])
+
+	return b, nil
+}
+
+// putString space-pads or truncates s to fill dst.
+func putString(dst []byte, s string) {
+	for i := range dst {
+		dst[i] = ' '
+	}
+	copy(dst, s)
+}
+
+// Write encodes r and writes the resulting 128-byte record to w.
+func (r *SAUCE) Write(w io.Writer) error {
+	b, err := r.Bytes()
 	if err != nil {
-		return
+		return err
 	}
-	if n != 128 {
-		return nil, errors.New("Short read")
+	_, err = w.Write(b)
+	return err
+}
+
+// commentBlockBytes encodes the COMNT subrecord that precedes the SAUCE
+// record when r.CommentLines is non-empty.
+func (r *SAUCE) commentBlockBytes() []byte {
+	if len(r.CommentLines) == 0 {
+		return nil
 	}
-	//if b[0] != ASCIISub {
-	//	return nil, errors.New("SUB character not found")
-	//}
-	if !bytes.Equal(b[0:5], SAUCEID[:]) {
-		return nil, errors.New("No SAUCE record")
+	b := make([]byte, 5+64*len(r.CommentLines))
+	copy(b[0:5], []byte("COMNT"))
+	for i, line := range r.CommentLines {
+		putString(b[5+64*i:5+64*(i+1)], line)
 	}
+	return b
+}
 
-	r = New()
-	r.Title = strings.TrimSpace(string(b[7:41]))
-	r.Author = strings.TrimSpace(string(b[41:61]))
-	r.Group = strings.TrimSpace(string(b[61:81]))
-	log.Printf("date: %q\n", string(b[82:90]))
-	r.Date = r.parseDate(string(b[82:90]))
-	r.FileSize = binary.LittleEndian.Uint32(b[91:95])
-	r.DataType = uint8(b[94])
-	r.FileType = uint8(b[95])
-	r.TInfo[0] = binary.LittleEndian.Uint16(b[96:98])
-	r.TInfo[1] = binary.LittleEndian.Uint16(b[98:100])
-	r.TInfo[2] = binary.LittleEndian.Uint16(b[100:102])
-	r.TInfo[3] = binary.LittleEndian.Uint16(b[102:104])
-	return r, nil
+// stripExisting returns data with any trailing ASCIISub marker, CommentBlock
+// and SAUCE record removed, or data unchanged if it carries no SAUCE record.
+func stripExisting(data []byte) []byte {
+	if len(data) < 128 {
+		return data
+	}
+	rec := data[len(data)-128:]
+	if !bytes.Equal(rec[0:5], SAUCEID[:]) {
+		return data
+	}
+
+	end := len(data) - 128
+	if n := int(rec[104]); n > 0 {
+		cbLen := 5 + 64*n
+		if start := end - cbLen; start >= 0 && bytes.Equal(data[start:start+5], []byte("COMNT")) {
+			end = start
+		}
+	}
+	if end > 0 && data[end-1] == ASCIISub {
+		end--
+	}
+	return data[:end]
+}
+
+// Embed atomically rewrites filename, stripping any SAUCE record it already
+// carries and appending the ASCIISub marker, an optional CommentBlock and
+// this record. r.FileSize is updated to the size of the payload preceding
+// the record.
+func (r *SAUCE) Embed(filename string) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	payload := stripExisting(data)
+	r.FileSize = uint32(len(payload))
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".sauce-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(payload); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err = tmp.Write([]byte{ASCIISub}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if cb := r.commentBlockBytes(); cb != nil {
+		if _, err = tmp.Write(cb); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err = r.Write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
 }
 
-func (r *SAUCE) parseDate(s string) time.Time {
-	y, _ := strconv.Atoi(s[:4])
-	m, _ := strconv.Atoi(s[4:6])
-	d, _ := strconv.Atoi(s[6:8])
-	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+// Strip removes an existing SAUCE record, its CommentBlock and the ASCIISub
+// marker from filename, rewriting it atomically. It is a no-op if filename
+// carries no SAUCE record.
+func Strip(filename string) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	payload := stripExisting(data)
+	if len(payload) == len(data) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".sauce-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(payload); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
 }
 
 // Dump the contents of the SAUCE record to stdout.
@@ -217,36 +474,98 @@ func (r *SAUCE) Dump() {
 	fmt.Printf("date....: %s\n", r.Date)
 	fmt.Printf("filesize: %d\n", r.FileSize)
 	fmt.Printf("datatype: %d (%s)\n", r.DataType, r.DataTypeString())
-	if SAUCEFileType[r.DataType] != nil {
+	if r.FileTypeString() != "" {
 		fmt.Printf("filetype: %d (%s)\n", r.FileType, r.FileTypeString())
 	} else {
 		fmt.Printf("filetype: %d\n", r.FileType)
 	}
 	fmt.Printf("tinfo...: %d, %d, %d, %d\n", r.TInfo[0], r.TInfo[1], r.TInfo[2], r.TInfo[3])
+	if size := DefaultRegistry.TInfoString(r); size != "" {
+		fmt.Printf("size....: %s\n", size)
+	}
 	switch r.DataType {
-	case 1:
-		switch r.FileType {
-		case 0, 1, 2, 4, 5, 8:
-			w := r.TInfo[0]
-			h := r.TInfo[1]
-			if w == 0 {
-				w = 80
-			}
-			fmt.Printf("size....: %d x %d characters\n", w, h)
-		case 3:
-			fmt.Printf("size....: %d x %d pixels\n", r.TInfo[0], r.TInfo[1])
-		}
-	case 2:
-		fmt.Printf("size....: %d x %d pixels\n", r.TInfo[0], r.TInfo[1])
+	case DATA_TYPE_CHARACTER, DATA_TYPE_BINARYTEXT, DATA_TYPE_XBIN:
+		r.dumpTInfoS()
+	}
+	for n, line := range r.CommentLines {
+		fmt.Printf("comment.: %2d: %s\n", n+1, line)
+	}
+}
+
+// dumpTInfoS prints the font/flag fields decoded from TFlags and TInfoS, for
+// DataTypes whose renderer needs them (Character, BinaryText, XBin).
+func (r *SAUCE) dumpTInfoS() {
+	if font := r.FontName(); font != "" {
+		fmt.Printf("font....: %s\n", font)
+	}
+	fmt.Printf("ice.....: %v\n", r.IceColors())
+	switch r.LetterSpacing() {
+	case LetterSpacing8Pixel:
+		fmt.Printf("spacing.: 8 pixel\n")
+	case LetterSpacing9Pixel:
+		fmt.Printf("spacing.: 9 pixel\n")
+	default:
+		fmt.Printf("spacing.: legacy\n")
+	}
+	switch r.AspectRatio() {
+	case AspectRatioStretch:
+		fmt.Printf("aspect..: stretch\n")
+	case AspectRatioSquare:
+		fmt.Printf("aspect..: square\n")
+	default:
+		fmt.Printf("aspect..: legacy\n")
+	}
+}
+
+// Letter-spacing values decoded from bits 1-2 of TFlags (the ANSiFlags byte
+// of SAUCE 00.5).
+const (
+	LetterSpacingLegacy = iota
+	LetterSpacing8Pixel
+	LetterSpacing9Pixel
+)
+
+// Aspect-ratio values decoded from bits 3-4 of TFlags.
+const (
+	AspectRatioLegacy = iota
+	AspectRatioStretch
+	AspectRatioSquare
+)
+
+// IceColors reports whether the ANSiFlags bit in TFlags marks the artwork as
+// using iCE colors (non-blink mode) rather than blinking text.
+func (r *SAUCE) IceColors() bool {
+	return r.TFlags&0x01 != 0
+}
+
+// LetterSpacing returns the font letter-spacing declared in TFlags, one of
+// LetterSpacingLegacy, LetterSpacing8Pixel or LetterSpacing9Pixel.
+func (r *SAUCE) LetterSpacing() int {
+	return int(r.TFlags>>1) & 0x03
+}
+
+// AspectRatio returns the pixel aspect ratio declared in TFlags, one of
+// AspectRatioLegacy, AspectRatioStretch or AspectRatioSquare.
+func (r *SAUCE) AspectRatio() int {
+	return int(r.TFlags>>3) & 0x03
+}
+
+// FontName returns the NUL-terminated font name stored in TInfoS, e.g.
+// "IBM VGA" or "Amiga Topaz 1", or "" if none was set.
+func (r *SAUCE) FontName() string {
+	n := bytes.IndexByte(r.TInfoS[:], 0)
+	if n < 0 {
+		n = len(r.TInfoS)
 	}
+	return string(r.TInfoS[:n])
 }
 
-// DataTypeString returns the DataType as string.
+// DataTypeString returns the DataType as string, per DefaultRegistry.
 func (r *SAUCE) DataTypeString() string {
-	return SAUCEDataType[r.DataType]
+	return DefaultRegistry.DataTypeString(r.DataType)
 }
 
-// FileTypeString returns the FileType as string.
+// FileTypeString returns the FileType as string, per DefaultRegistry.
 func (r *SAUCE) FileTypeString() string {
-	return SAUCEFileType[r.DataType][r.FileType]
+	return DefaultRegistry.FileTypeString(r.DataType, r.FileType)
 }
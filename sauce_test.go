@@ -0,0 +1,126 @@
+package sauce
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBytesParseRoundTrip(t *testing.T) {
+	r := New()
+	r.Title = "Test Title"
+	r.Author = "Test Author"
+	r.Group = "Test Group"
+	r.Date = time.Date(2020, 5, 17, 0, 0, 0, 0, time.UTC)
+	r.FileSize = 1234
+	r.DataType = DATA_TYPE_CHARACTER
+	r.FileType = 1
+	r.TInfo = [4]uint16{80, 25, 0, 0}
+	r.TFlags = 0x05 // iCE colors (bit 0) + 8-pixel letter spacing (bits 1-2)
+	copy(r.TInfoS[:], "IBM VGA")
+
+	b, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	if len(b) != 128 {
+		t.Fatalf("Bytes returned %d bytes, want 128", len(b))
+	}
+
+	got, err := ParseBytes(append([]byte("payload preceding the record"), b...))
+	if err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+
+	if got.Title != r.Title || got.Author != r.Author || got.Group != r.Group {
+		t.Errorf("Title/Author/Group = %q/%q/%q, want %q/%q/%q",
+			got.Title, got.Author, got.Group, r.Title, r.Author, r.Group)
+	}
+	if !got.Date.Equal(r.Date) {
+		t.Errorf("Date = %v, want %v", got.Date, r.Date)
+	}
+	if got.FileSize != r.FileSize {
+		t.Errorf("FileSize = %d, want %d", got.FileSize, r.FileSize)
+	}
+	if got.DataType != r.DataType || got.FileType != r.FileType {
+		t.Errorf("DataType/FileType = %d/%d, want %d/%d", got.DataType, got.FileType, r.DataType, r.FileType)
+	}
+	if got.TInfo != r.TInfo {
+		t.Errorf("TInfo = %v, want %v", got.TInfo, r.TInfo)
+	}
+	if got.TFlags != r.TFlags {
+		t.Errorf("TFlags = %#x, want %#x", got.TFlags, r.TFlags)
+	}
+	if got.FontName() != r.FontName() {
+		t.Errorf("FontName = %q, want %q", got.FontName(), r.FontName())
+	}
+}
+
+func TestEmbedParseStripRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "art.ans")
+	payload := []byte("\x1b[1;37mHELLO\x1b[0m")
+	if err := os.WriteFile(path, payload, 0755); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	r := New()
+	r.Title = "Embed Test"
+	r.DataType = DATA_TYPE_CHARACTER
+	r.FileType = 1
+	r.TInfo[0] = 80
+	if err := r.AddComment("hello"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := r.Embed(path); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatalf("Stat: %v", err)
+	} else if info.Mode() != 0755 {
+		t.Errorf("mode after Embed = %v, want %v", info.Mode(), os.FileMode(0755))
+	}
+
+	got, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got.Title != r.Title {
+		t.Errorf("Title = %q, want %q", got.Title, r.Title)
+	}
+	if len(got.CommentLines) != 1 || got.CommentLines[0] != "hello" {
+		t.Errorf("CommentLines = %v, want [hello]", got.CommentLines)
+	}
+	if got.FileSize != uint32(len(payload)) {
+		t.Errorf("FileSize = %d, want %d", got.FileSize, len(payload))
+	}
+
+	if err := Strip(path); err != nil {
+		t.Fatalf("Strip: %v", err)
+	}
+	stripped, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(stripped, payload) {
+		t.Errorf("stripped content = %q, want %q", stripped, payload)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatalf("Stat: %v", err)
+	} else if info.Mode() != 0755 {
+		t.Errorf("mode after Strip = %v, want %v", info.Mode(), os.FileMode(0755))
+	}
+}
+
+func TestAddCommentMax(t *testing.T) {
+	r := New()
+	for i := 0; i < maxComments; i++ {
+		if err := r.AddComment("line"); err != nil {
+			t.Fatalf("AddComment(%d): %v", i, err)
+		}
+	}
+	if err := r.AddComment("one too many"); err == nil {
+		t.Fatal("AddComment past maxComments: want error, got nil")
+	}
+}